@@ -0,0 +1,175 @@
+package lcache
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+// bloomFilter is a standard Bloom filter using double hashing (Kirsch-
+// Mitzenmacher) to derive its k hash functions from two independent hashes.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint
+}
+
+func newBloomFilter(expectedN uint, fpRate float64) *bloomFilter {
+	m := optimalBloomM(expectedN, fpRate)
+	k := optimalBloomK(m, expectedN)
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func optimalBloomM(n uint, p float64) uint64 {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	if m < 64 {
+		m = 64
+	}
+	return uint64(m)
+}
+
+func optimalBloomK(m uint64, n uint) uint {
+	if n == 0 {
+		return 1
+	}
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return uint(k)
+}
+
+func (b *bloomFilter) hashes(key string) (uint64, uint64) {
+	h1 := fnv.New64a()
+	h1.Write([]byte(key))
+	h2 := fnv.New32a()
+	h2.Write([]byte(key))
+	return h1.Sum64(), uint64(h2.Sum32())
+}
+
+// Add records key as present in the filter.
+func (b *bloomFilter) Add(key string) {
+	h1, h2 := b.hashes(key)
+	for i := uint(0); i < b.k; i++ {
+		idx := (h1 + uint64(i)*h2) % b.m
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Test reports whether key may have been added (false positives possible,
+// false negatives never).
+func (b *bloomFilter) Test(key string) bool {
+	h1, h2 := b.hashes(key)
+	for i := uint(0); i < b.k; i++ {
+		idx := (h1 + uint64(i)*h2) % b.m
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// negativeCache tracks keys whose fn returned the registered "not found"
+// error, so that later Gets for the same key can be rejected immediately
+// instead of calling fn again. It rolls over to a fresh Bloom filter every
+// expectedN additions or every ttl, whichever comes first, so that keys
+// which are no longer actually missing eventually stop matching instead of
+// accumulating forever; ttl bounds how stale a "known missing" verdict can
+// get under low traffic, when the count-based rollover alone might not
+// trigger for a long time.
+type negativeCache struct {
+	mu        sync.Mutex
+	expectedN uint
+	fpRate    float64
+	ttl       time.Duration
+	active    *bloomFilter
+	previous  *bloomFilter
+	count     uint
+	rotatedAt time.Time
+}
+
+func newNegativeCache(expectedN uint, fpRate float64, ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		expectedN: expectedN,
+		fpRate:    fpRate,
+		ttl:       ttl,
+		active:    newBloomFilter(expectedN, fpRate),
+		rotatedAt: time.Now(),
+	}
+}
+
+// Add records that key's fn call returned the "not found" sentinel.
+func (nc *negativeCache) Add(key string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.rotateIfDueLocked()
+	nc.active.Add(key)
+	nc.count++
+	if nc.count >= nc.expectedN {
+		nc.rotateLocked()
+	}
+}
+
+// Test reports whether key is known to be missing.
+func (nc *negativeCache) Test(key string) bool {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.rotateIfDueLocked()
+	if nc.active.Test(key) {
+		return true
+	}
+	return nc.previous != nil && nc.previous.Test(key)
+}
+
+// rotateIfDueLocked rotates generations if ttl is set and has elapsed since
+// the last rotation. Must be called with nc.mu held.
+func (nc *negativeCache) rotateIfDueLocked() {
+	if nc.ttl > 0 && time.Since(nc.rotatedAt) >= nc.ttl {
+		nc.rotateLocked()
+	}
+}
+
+// rotateLocked demotes active to previous and starts a fresh generation.
+// Must be called with nc.mu held.
+func (nc *negativeCache) rotateLocked() {
+	nc.previous = nc.active
+	nc.active = newBloomFilter(nc.expectedN, nc.fpRate)
+	nc.count = 0
+	nc.rotatedAt = time.Now()
+}
+
+// WithNegativeCache returns a Option which short-circuits Get for keys
+// known, via a rolling Bloom filter, to have previously resolved to the
+// error registered with WithNotFoundError. expectedN and fpRate size the
+// underlying filter the same way they would for any Bloom filter: expectedN
+// is the number of distinct missing keys you expect to track before the
+// filter rolls over, and fpRate is the acceptable false-positive rate. Pair
+// with WithNegativeCacheTTL to also bound generations by time.
+func WithNegativeCache(expectedN uint, fpRate float64) Option {
+	return func(o *options) {
+		o.negativeCacheEnabled = true
+		o.negativeCacheExpectedN = expectedN
+		o.negativeCacheFPRate = fpRate
+	}
+}
+
+// WithNegativeCacheTTL returns a Option which rolls the negative cache's
+// Bloom filter over to a fresh generation every ttl, in addition to the
+// count-based rollover from WithNegativeCache. Without it, a key added under
+// low traffic can stay falsely "known missing" indefinitely if the filter
+// never fills up. Has no effect unless WithNegativeCache is also given.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeCacheTTL = ttl
+	}
+}
+
+// WithNotFoundError returns a Option which registers the sentinel error fn
+// returns for a key that doesn't exist, so WithNegativeCache knows which
+// errors are worth remembering.
+func WithNotFoundError(err error) Option {
+	return func(o *options) {
+		o.notFoundErr = err
+	}
+}
@@ -0,0 +1,91 @@
+package lcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBloomFilterNoFalseNegatives(t *testing.T) {
+	bf := newBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		bf.Add(string(rune(i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if !bf.Test(string(rune(i))) {
+			t.Fatalf("expected key %d to test positive after being added", i)
+		}
+	}
+}
+
+func TestNegativeCacheShortCircuitsKnownMissingKeys(t *testing.T) {
+	errNotFound := errors.New("not found")
+	calls := 0
+	fn := func(id int) (interface{}, error) {
+		calls++
+		if id == 1 {
+			return nil, errNotFound
+		}
+		return id, nil
+	}
+	c, _ := New(fn, time.Hour, WithNotFoundError(errNotFound), WithNegativeCache(1000, 0.01))
+
+	_, err := c.Get(1)
+	if err != errNotFound {
+		t.Fatalf("expected errNotFound, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected fn to be called once, got %d", calls)
+	}
+
+	// Remove the cached (negative) item so the next Get would otherwise
+	// fall through to fn again -- the bloom filter should still catch it.
+	c.Remove(1)
+	_, err = c.Get(1)
+	if err != errNotFound {
+		t.Fatalf("expected errNotFound from negative cache, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected fn not to be called again, still got %d calls", calls)
+	}
+
+	// an unrelated, never-seen key must still go through fn.
+	val, err := c.Get(2)
+	if err != nil || val != 2 {
+		t.Fatalf("expected (2, nil), got (%v, %v)", val, err)
+	}
+}
+
+func TestNegativeCacheTTLAgesOutStaleEntries(t *testing.T) {
+	errNotFound := errors.New("not found")
+	calls := 0
+	fn := func(id int) (interface{}, error) {
+		calls++
+		if id == 1 && calls == 1 {
+			return nil, errNotFound
+		}
+		return id, nil
+	}
+	c, _ := New(fn, time.Hour,
+		WithNotFoundError(errNotFound),
+		WithNegativeCache(1000, 0.01),
+		WithNegativeCacheTTL(10*time.Millisecond),
+	)
+
+	_, err := c.Get(1)
+	if err != errNotFound {
+		t.Fatalf("expected errNotFound, got %v", err)
+	}
+	c.Remove(1)
+
+	// one ttl only rotates key 1 from active into previous, where it still
+	// matches; two full ttls are needed to flush it out of both generations.
+	time.Sleep(20 * time.Millisecond)
+	c.Get(1)
+	time.Sleep(20 * time.Millisecond)
+
+	val, err := c.Get(1)
+	if err != nil || val != 1 {
+		t.Fatalf("expected the negative cache entry to have aged out, got (%v, %v)", val, err)
+	}
+}
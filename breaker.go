@@ -0,0 +1,106 @@
+package lcache
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen indicates the circuit breaker protecting the underlying fn
+// is currently tripped, so the call was short-circuited instead of invoking
+// fn again.
+var ErrCircuitOpen = errors.New("lcache: circuit breaker is open")
+
+const (
+	breakerBucketInterval = time.Second
+	breakerNumBuckets     = 10
+	// defaultBreakerK is the default K used in the adaptive drop formula,
+	// see circuitBreaker.Allow.
+	defaultBreakerK = 1.5
+)
+
+// breakerBucket tracks requests/accepts observed during one second.
+type breakerBucket struct {
+	ts       int64
+	requests int64
+	accepts  int64
+}
+
+// circuitBreaker implements a Google SRE style adaptive client-side breaker:
+// instead of a hard open/closed state machine, each refresh is allowed
+// through with probability 1-dropProbability, where dropProbability grows
+// as the observed request volume outpaces accepts over a rolling window.
+// See https://sre.google/sre-book/handling-overload/#eq2101.
+type circuitBreaker struct {
+	mu      sync.Mutex
+	k       float64
+	buckets [breakerNumBuckets]breakerBucket
+}
+
+func newCircuitBreaker(k float64) *circuitBreaker {
+	if k <= 0 {
+		k = defaultBreakerK
+	}
+	return &circuitBreaker{k: k}
+}
+
+// bucket returns the bucket for now, resetting it first if it belongs to a
+// second that has since rolled out of the window.
+func (b *circuitBreaker) bucket(now time.Time) *breakerBucket {
+	sec := now.Unix()
+	buk := &b.buckets[sec%breakerNumBuckets]
+	if buk.ts != sec {
+		buk.ts = sec
+		buk.requests = 0
+		buk.accepts = 0
+	}
+	return buk
+}
+
+func (b *circuitBreaker) totals(now time.Time) (requests, accepts int64) {
+	cutoff := now.Unix() - breakerNumBuckets
+	for i := range b.buckets {
+		buk := &b.buckets[i]
+		if buk.ts > cutoff {
+			requests += buk.requests
+			accepts += buk.accepts
+		}
+	}
+	return
+}
+
+// Allow reports whether a refresh should be let through to call fn. It also
+// records the attempt as a request in the rolling window.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	requests, accepts := b.totals(now)
+	dropProbability := math.Max(0, (float64(requests)-b.k*float64(accepts))/(float64(requests)+1))
+
+	b.bucket(now).requests++
+	return rand.Float64() >= dropProbability
+}
+
+// Accept records that a call to fn that was let through succeeded.
+func (b *circuitBreaker) Accept() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).accepts++
+}
+
+// WithCircuitBreaker returns a Option which wraps fn with an adaptive
+// circuit breaker: once requests to fn start failing, a growing fraction of
+// refreshes are short-circuited with ErrCircuitOpen (or simply serve the
+// last good value) instead of hammering a struggling dependency. k tunes
+// how aggressively the breaker trips; the default of 1.5 tolerates some
+// failures before dropping requests.
+func WithCircuitBreaker(k float64) Option {
+	return func(o *options) {
+		o.enableCircuitBreaker = true
+		o.breakerK = k
+	}
+}
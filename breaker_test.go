@@ -0,0 +1,46 @@
+package lcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerShortCircuits(t *testing.T) {
+	failing := errors.New("boom")
+	fn := func() (interface{}, error) {
+		return nil, failing
+	}
+	c, _ := New(fn, time.Millisecond, WithCircuitBreaker(1.5))
+
+	var sawCircuitOpen bool
+	for i := 0; i < 1000; i++ {
+		_, err := c.Get()
+		if err == ErrCircuitOpen {
+			sawCircuitOpen = true
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !sawCircuitOpen {
+		t.Errorf("expected circuit breaker to eventually trip and return ErrCircuitOpen")
+	}
+}
+
+func TestCircuitBreakerAllowsHealthyFn(t *testing.T) {
+	fn := func() (interface{}, error) {
+		return "ok", nil
+	}
+	c, _ := New(fn, time.Millisecond, WithCircuitBreaker(1.5))
+
+	for i := 0; i < 50; i++ {
+		val, err := c.Get()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if val != "ok" {
+			t.Fatalf("unexpected value: %v", val)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
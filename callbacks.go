@@ -0,0 +1,46 @@
+package lcache
+
+// EvictReason describes why an entry left the container, passed to the
+// OnEvict callback registered via WithOnEvict.
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was evicted to make room under the
+	// container's capacity or eviction policy.
+	EvictCapacity EvictReason = iota
+	// EvictExpired means the entry was dropped by the TTL sweeper.
+	EvictExpired
+	// EvictManual means the entry was removed by an explicit Remove call.
+	EvictManual
+	// EvictPurge means the entry was dropped by a Purge call.
+	EvictPurge
+)
+
+// WithOnEvict returns a Option which registers a callback invoked whenever
+// an entry leaves the container because of capacity eviction, expiration,
+// Remove, or Purge. It runs after the container lock has been released, so
+// it is safe to call back into Get/Remove/Purge from inside it.
+func WithOnEvict(fn func(key string, params []interface{}, value interface{}, reason EvictReason)) Option {
+	return func(o *options) {
+		o.onEvict = fn
+	}
+}
+
+// WithOnRefresh returns a Option which registers a callback invoked after
+// every attempt to refresh an item's value via fn, whether it succeeded or
+// not. It runs in the same background goroutine item.refresh already uses,
+// so it never blocks a concurrent Get.
+func WithOnRefresh(fn func(key string, old, new interface{}, err error)) Option {
+	return func(o *options) {
+		o.onRefresh = fn
+	}
+}
+
+// WithOnError returns a Option which registers a callback invoked whenever
+// a refresh ends with a non-nil error, including ErrCircuitOpen. It runs
+// alongside WithOnRefresh's callback.
+func WithOnError(fn func(key string, err error)) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
@@ -0,0 +1,87 @@
+package lcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnEvictFiresOnCapacityEviction(t *testing.T) {
+	fn := func(x int) (interface{}, error) {
+		return x, nil
+	}
+
+	var mu sync.Mutex
+	var evictedKeys []string
+	var reasons []EvictReason
+	c, _ := New(fn, time.Minute, WithCapacity(1), WithLRU(), WithOnEvict(func(key string, params []interface{}, value interface{}, reason EvictReason) {
+		mu.Lock()
+		evictedKeys = append(evictedKeys, key)
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}))
+
+	c.Get(1)
+	c.Get(2)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(evictedKeys) != 1 {
+		t.Fatalf("expected exactly one eviction, got %v", evictedKeys)
+	}
+	if reasons[0] != EvictCapacity {
+		t.Errorf("expected EvictCapacity, got %v", reasons[0])
+	}
+}
+
+func TestOnEvictFiresOnRemoveAndPurge(t *testing.T) {
+	fn := func(x int) (interface{}, error) {
+		return x, nil
+	}
+
+	var mu sync.Mutex
+	count := 0
+	var reasons []EvictReason
+	c, _ := New(fn, time.Minute, WithOnEvict(func(key string, params []interface{}, value interface{}, reason EvictReason) {
+		mu.Lock()
+		count++
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}))
+
+	c.Get(1)
+	c.Get(2)
+	c.Remove(1)
+	c.Purge()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 2 {
+		t.Errorf("expected 2 eviction callbacks, got %d", count)
+	}
+	if len(reasons) == 2 && (reasons[0] != EvictManual || reasons[1] != EvictPurge) {
+		t.Errorf("expected [EvictManual, EvictPurge], got %v", reasons)
+	}
+}
+
+func TestOnRefreshAndOnError(t *testing.T) {
+	fn := func() (interface{}, error) {
+		return "value", nil
+	}
+
+	refreshed := make(chan struct{}, 1)
+	c, _ := New(fn, time.Millisecond, WithOnRefresh(func(key string, old, new interface{}, err error) {
+		select {
+		case refreshed <- struct{}{}:
+		default:
+		}
+	}))
+
+	c.Get()
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("expected OnRefresh to fire")
+	}
+}
@@ -0,0 +1,111 @@
+package lcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetTreatsExpiredEntryAsMiss(t *testing.T) {
+	calls := 0
+	release := make(chan struct{}, 1)
+	fn := func() (int, error) {
+		calls++
+		if calls > 1 {
+			<-release
+		}
+		return calls, nil
+	}
+	c, err := New(fn, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	first, _ := c.Get()
+	if first != 1 {
+		t.Fatalf("expected the first call to return 1, got %v", first)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Get on an expired entry counts as a miss, but still serves the stale
+	// value immediately while fn reloads in the background, rather than
+	// every caller blocking on a fresh load.
+	start := time.Now()
+	stale, _ := c.Get()
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("expected Get on an expired entry to return immediately, took %v", elapsed)
+	}
+	if stale != 1 {
+		t.Fatalf("expected the stale value 1 while refreshing, got %v", stale)
+	}
+	if st := c.Stats(); st.Misses != 2 || st.Hits != 0 {
+		t.Errorf("expected the expired Get to count as a miss (2 misses, 0 hits), got %+v", st)
+	}
+
+	release <- struct{}{}
+	time.Sleep(20 * time.Millisecond)
+
+	fresh, _ := c.Get()
+	if fresh != 2 {
+		t.Fatalf("expected the refreshed value 2, got %v", fresh)
+	}
+	if calls != 2 {
+		t.Errorf("expected fn to be called exactly twice, got %d", calls)
+	}
+}
+
+func TestDeleteExpiredSweepsStaleEntries(t *testing.T) {
+	fn := func(x int) (int, error) { return x, nil }
+	c, err := New(fn, 10*time.Millisecond, WithLRU())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1)
+	c.Get(2)
+	time.Sleep(20 * time.Millisecond)
+
+	var mu sync.Mutex
+	var reasons []EvictReason
+	c.opts.onEvict = func(key string, params []interface{}, value interface{}, reason EvictReason) {
+		mu.Lock()
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}
+	c.DeleteExpired()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 2 {
+		t.Fatalf("expected 2 expired entries to be swept, got %d", len(reasons))
+	}
+	for _, r := range reasons {
+		if r != EvictExpired {
+			t.Errorf("expected EvictExpired, got %v", r)
+		}
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected an empty container after sweeping, got %d", c.Len())
+	}
+}
+
+func TestCleanupIntervalRunsJanitorUntilClose(t *testing.T) {
+	fn := func(x int) (int, error) { return x, nil }
+	c, err := New(fn, 10*time.Millisecond, WithCleanupInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	c.Get(1)
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected janitor to have swept the expired entry, len=%d", c.Len())
+	}
+
+	c.Close()
+	c.Close() // Close must be safe to call more than once
+}
@@ -2,7 +2,6 @@ package lcache
 
 import (
 	"bytes"
-	"container/list"
 	"context"
 	"errors"
 	"fmt"
@@ -25,13 +24,43 @@ var (
 	// ErrResourceExhausted indicates underlying resouce exhausted, the response from underlying
 	// api or interface won't be cached.
 	ErrResourceExhausted = errors.New("resouce exhausted")
+
+	// ErrMissingStoreOutType indicates WithSecondaryStore was given a nil
+	// outType, which Codec.Decode needs in order to know what concrete type
+	// to unmarshal a stored value back into.
+	ErrMissingStoreOutType = errors.New("secondary store requires an explicit out type")
 )
 
 type options struct {
 	cacheKeyGenerator CacheKeyGenerator
 	enableLRU         bool
+	enableLFU         bool
 	capacity          int
 	contextSupport    bool
+	shards            int
+	policy            Policy
+	cleanupInterval   time.Duration
+	negativeTTL       time.Duration
+	stats             *containerStats
+	metricsSink       MetricsSink
+
+	enableCircuitBreaker bool
+	breakerK             float64
+
+	onEvict   func(key string, params []interface{}, value interface{}, reason EvictReason)
+	onRefresh func(key string, old, new interface{}, err error)
+	onError   func(key string, err error)
+
+	store        Store
+	codec        Codec
+	storeOutType reflect.Type
+
+	negativeCache          *negativeCache
+	negativeCacheEnabled   bool
+	negativeCacheExpectedN uint
+	negativeCacheFPRate    float64
+	negativeCacheTTL       time.Duration
+	notFoundErr            error
 }
 
 // Option configures how we set up the container
@@ -51,6 +80,14 @@ func WithLRU() Option {
 	}
 }
 
+// WithLFU returns a Option which enable lfu evict algorithm in container.
+// It takes precedence over WithLRU if both are given.
+func WithLFU() Option {
+	return func(o *options) {
+		o.enableLFU = true
+	}
+}
+
 // WithCapacity returns a Option which set the capacity of container.
 func WithCapacity(capacity int) Option {
 	return func(o *options) {
@@ -65,20 +102,54 @@ func WithContextSupport() Option {
 	}
 }
 
+// WithCleanupInterval returns a Option which launches a background janitor
+// goroutine that wakes up every interval and evicts entries whose TTL has
+// passed, firing the OnEvict callback with reason EvictExpired. Without it,
+// expired entries stay in the container until the next Get refreshes them
+// or DeleteExpired is called. Call Container.Close to stop the goroutine.
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.cleanupInterval = interval
+	}
+}
+
+// WithNegativeTTL returns a Option which caches a failed fn call for ttl
+// instead of the container's normal TTL, so a misbehaving backend doesn't
+// keep serving the same error for as long as a successful lookup would be
+// cached. Unset, errors are cached for the normal TTL like any other value.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithShards returns a Option which splits the container into n shards, each
+// guarded by its own lock and holding its own eviction state, so that Get
+// calls for unrelated keys no longer serialize on a single mutex. n is
+// rounded up to the next power of two; the default is a single shard.
+func WithShards(n int) Option {
+	return func(o *options) {
+		o.shards = n
+	}
+}
+
 // Container implements a thread-safe cache container
 type Container struct {
-	sync.RWMutex
 	opts *options
 
-	elements  map[string]*list.Element // lru releated elements
-	evictList *list.List
+	shards    []*shard
+	shardMask uint32
+
+	breaker *circuitBreaker
 
 	fn       interface{}
 	fnKind   reflect.Kind
 	fnNumIn  int
 	fnNumOut int
 	ttl      time.Duration
-	items    map[string]*item
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
 }
 
 // New create a cache container with default capacity and given parameters.
@@ -105,6 +176,7 @@ func newContainer(fn interface{}, ttl time.Duration, opt ...Option) (*Container,
 	if opts.capacity <= 0 {
 		opts.capacity = DefaultCapacity
 	}
+	opts.stats = &containerStats{}
 
 	t := reflect.TypeOf(fn)
 	if t.Kind() != reflect.Func || t.NumOut() != 2 {
@@ -122,15 +194,85 @@ func newContainer(fn interface{}, ttl time.Duration, opt ...Option) (*Container,
 	if c.opts.cacheKeyGenerator == nil {
 		c.opts.cacheKeyGenerator = c.generateCacheKey
 	}
-	if c.opts.enableLRU {
-		c.evictList = list.New()
-		c.elements = make(map[string]*list.Element)
-	} else {
-		c.items = make(map[string]*item)
+	if c.opts.enableCircuitBreaker {
+		c.breaker = newCircuitBreaker(c.opts.breakerK)
+	}
+	if c.opts.store != nil {
+		if c.opts.codec == nil {
+			c.opts.codec = JSONCodec{}
+		}
+		if c.opts.storeOutType == nil {
+			return nil, ErrMissingStoreOutType
+		}
+	}
+	if c.opts.negativeCacheEnabled {
+		c.opts.negativeCache = newNegativeCache(c.opts.negativeCacheExpectedN, c.opts.negativeCacheFPRate, c.opts.negativeCacheTTL)
+	}
+
+	// A Policy is itself the single source of truth for eviction order, so
+	// sharding it would only fragment that order across independent,
+	// differently-sized instances; a Policy-driven container stays single-shard.
+	if opts.policy != nil {
+		opts.shards = 1
+	}
+	numShards := nextPowerOfTwo(opts.shards)
+	c.shardMask = uint32(numShards - 1)
+	c.shards = make([]*shard, numShards)
+	for i := range c.shards {
+		c.shards[i] = newShard(opts)
+	}
+
+	if ttl > 0 && opts.cleanupInterval > 0 {
+		c.closeCh = make(chan struct{})
+		go c.runJanitor(opts.cleanupInterval)
 	}
 	return c, nil
 }
 
+// runJanitor periodically sweeps expired entries until Close is called.
+func (c *Container) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+// DeleteExpired walks every shard and evicts entries whose TTL has passed,
+// firing the OnEvict callback with reason EvictExpired. Containers created
+// with WithCleanupInterval do this automatically in the background; this is
+// for callers who'd rather sweep manually (or not use a janitor at all).
+func (c *Container) DeleteExpired() {
+	for _, s := range c.shards {
+		s.sweepExpired()
+	}
+}
+
+// Close stops the background janitor goroutine started by
+// WithCleanupInterval, if any. It is safe to call more than once, and safe
+// to call even if no janitor was started.
+func (c *Container) Close() {
+	c.closeOnce.Do(func() {
+		if c.closeCh != nil {
+			close(c.closeCh)
+		}
+	})
+}
+
+// nextPowerOfTwo rounds n up to the next power of two, with a minimum of 1.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
 func (c *Container) generateCacheKey(params ...interface{}) string {
 	buf := bytes.NewBufferString("")
 	if c.opts.contextSupport {
@@ -147,6 +289,11 @@ func (c *Container) generateCacheKey(params ...interface{}) string {
 // CacheKeyGenerator generates cache key for the given parameters.
 type CacheKeyGenerator func(params ...interface{}) string
 
+// shardFor returns the shard that owns key.
+func (c *Container) shardFor(key string) *shard {
+	return c.shards[hashKey(key)&c.shardMask]
+}
+
 // Get is used to obtain the value with the given parameters. If the params string
 // has in the container, it will return immediately. Otherwise, it will load data
 // with the fn callback.
@@ -157,97 +304,27 @@ func (c *Container) Get(params ...interface{}) (interface{}, error) {
 	}
 
 	key := c.opts.cacheKeyGenerator(params...)
-	if !c.opts.enableLRU {
-		if itm, ok := c.items[key]; ok {
-			return itm.Value()
-		}
-		c.Lock()
-		itm := c.getLocked(params, key)
-		c.Unlock()
-		return itm.Value()
-	}
+	shard := c.shardFor(key)
 
-	if ent, ok := c.elements[key]; ok {
-		c.Lock()
-		c.evictList.MoveToFront(ent)
-		c.Unlock()
-		return ent.Value.(*item).Value()
+	if nc := c.opts.negativeCache; nc != nil && !shard.has(key) && nc.Test(key) {
+		return nil, c.opts.notFoundErr
 	}
 
-	c.Lock()
-	ent := c.getLockedLRU(params, key)
-	c.Unlock()
-	return ent.Value.(*item).Value()
-
-}
-
-func (c *Container) getLocked(params []interface{}, key string) *item {
-	if itm, ok := c.items[key]; ok {
-		return itm
-	}
-
-	itm := newItem(c, key, params)
-	// copy on write
-	items := make(map[string]*item, len(c.items)+1)
-	for k, v := range c.items {
-		items[k] = v
+	itm := shard.get(c, params, key)
+	val, err := itm.Value()
+	if nc := c.opts.negativeCache; nc != nil && err == c.opts.notFoundErr && err != nil {
+		nc.Add(key)
 	}
-	items[key] = itm
-	c.items = items
-
-	return itm
-}
-
-func (c *Container) getLockedLRU(params []interface{}, key string) *list.Element {
-	if ent, ok := c.elements[key]; ok {
-		c.evictList.MoveToFront(ent)
-		return ent
-	}
-
-	itm := newItem(c, key, params)
-	ent := c.evictList.PushFront(itm)
-
-	// copy on write
-	elements := make(map[string]*list.Element, len(c.elements)+1)
-	for k, v := range c.elements {
-		elements[k] = v
-	}
-	elements[key] = ent
-	c.elements = elements
-	if c.evictList.Len() > c.opts.capacity {
-		c.removeOldestElement()
-	}
-	return ent
-}
-
-// removeOldest removes the oldest item from the container.
-func (c *Container) removeOldestElement() {
-	ent := c.evictList.Back()
-	if ent != nil {
-		c.removeElement(ent)
-	}
-}
-
-// removeElement is used to remove a given list element from the container.
-func (c *Container) removeElement(e *list.Element) {
-	c.evictList.Remove(e)
-	itm := e.Value.(*item)
-	delete(c.elements, itm.key)
+	return val, err
 }
 
 // Purge is used to completely clear the container
 func (c *Container) Purge() {
-	c.Lock()
-	defer c.Unlock()
-	if c.opts.enableLRU {
-		for key := range c.elements {
-			delete(c.elements, key)
-		}
-		c.evictList.Init()
-	} else {
-		for key := range c.items {
-			delete(c.items, key)
-		}
+	for _, s := range c.shards {
+		s.purge()
+	}
+	if c.opts.store != nil {
+		c.opts.store.Purge()
 	}
 }
 
@@ -255,30 +332,39 @@ func (c *Container) Purge() {
 // params key was contained.
 func (c *Container) Remove(params ...interface{}) bool {
 	key := c.opts.cacheKeyGenerator(params...)
-	c.Lock()
-	defer c.Unlock()
-	if c.opts.enableLRU {
-		if ent, ok := c.elements[key]; ok {
-			c.removeElement(ent)
-			return true
-		}
-	} else {
-		if _, ok := c.items[key]; ok {
-			delete(c.items, key)
-			return true
-		}
+	removed := c.shardFor(key).remove(key)
+	if c.opts.store != nil {
+		c.opts.store.Delete(key)
 	}
-	return false
+	return removed
 }
 
 // Len returns the number of items in the container
 func (c *Container) Len() int {
-	c.RLock()
-	defer c.RUnlock()
-	if c.opts.enableLRU {
-		return len(c.elements)
+	n := 0
+	for _, s := range c.shards {
+		n += s.len()
+	}
+	return n
+}
+
+// Stats returns a snapshot of the container's hit/miss/eviction counters
+// and its loader-latency EWMA.
+func (c *Container) Stats() Stats {
+	return c.opts.stats.snapshot()
+}
+
+// ResetStats zeroes every counter returned by Stats.
+func (c *Container) ResetStats() {
+	c.opts.stats.reset()
+}
+
+// recordLoadLatency updates the loader-latency EWMA for a real fn call.
+func (c *Container) recordLoadLatency(d time.Duration) {
+	c.opts.stats.recordLoadLatency(d)
+	if c.opts.metricsSink != nil {
+		c.opts.metricsSink.ObserveLoadLatency(d)
 	}
-	return len(c.items)
 }
 
 // item is used to hold a value
@@ -307,20 +393,50 @@ func newItem(c *Container, key string, params []interface{}) *item {
 	}
 }
 
+// Expired reports whether i has loaded a value and its TTL has since
+// passed. An item that hasn't loaded yet is never "expired" -- it's still
+// in its first load, and concurrent callers should wait on that rather than
+// have the shard evict it out from under them.
+func (i *item) Expired() bool {
+	i.Lock()
+	defer i.Unlock()
+	return i.initialed && !time.Now().Before(i.expireAt)
+}
+
 // Value returns the real value in the item. If real value has been loaded,
 // it will return immediately. Otherwise, it will return until the real value
 // is initialed.
 func (i *item) Value() (val interface{}, err error) {
-	if time.Now().Before(i.expireAt) {
-		return i.value, i.err
+	i.Lock()
+	fresh := time.Now().Before(i.expireAt)
+	if fresh {
+		val, err = i.value, i.err
 	}
+	i.Unlock()
+	if fresh {
+		return val, err
+	}
+
 	i.Refresh()
-	// if item has not initialed, wait until initial done.
-	// else return old value directly
-	if !i.initialed {
+	i.Wait()
+
+	i.Lock()
+	val, err = i.value, i.err
+	i.Unlock()
+	return val, err
+}
+
+// Wait blocks until the item's first load has completed. It returns
+// immediately if the item has already been initialed, so concurrent misses
+// on the same key all wait on the one in-flight fn call started by whichever
+// of them called Refresh first, instead of each calling fn themselves.
+func (i *item) Wait() {
+	i.Lock()
+	initialed := i.initialed
+	i.Unlock()
+	if !initialed {
 		<-i.initialCh
 	}
-	return i.value, i.err
 }
 
 // Refresh is used to refresh real value with fn callback.
@@ -331,28 +447,121 @@ func (i *item) Refresh() {
 		return
 	}
 	i.refreshing = true
-	go i.refresh()
 	i.Unlock()
-	return
+	go i.refresh()
 }
 
+// refresh runs fn (or consults the secondary store) and applies the result.
+// It runs in its own goroutine per Refresh, so every mutation of i's shared
+// fields is done under i.Lock -- only the potentially slow work (store I/O,
+// fn itself, callbacks) happens outside the lock.
 func (i *item) refresh() {
+	if i.c.breaker != nil && !i.c.breaker.Allow() {
+		// short-circuit: keep the last good value around but surface that
+		// the breaker is open instead of calling fn again.
+		i.Lock()
+		old := i.value
+		i.err = ErrCircuitOpen
+		i.expireAt = time.Now().Add(i.cacheTTL(ErrCircuitOpen))
+		i.refreshing = false
+		firstLoad := !i.initialed
+		if firstLoad {
+			i.initialed = true
+		}
+		i.Unlock()
+		if firstLoad {
+			close(i.initialCh)
+		}
+		i.fireCallbacks(old, old, ErrCircuitOpen)
+		return
+	}
+
+	i.Lock()
+	old := i.value
+	i.Unlock()
+
+	// consult the secondary store before calling fn
+	if store := i.c.opts.store; store != nil {
+		if data, expireAt, ok := store.Get(i.key); ok && time.Now().Before(expireAt) {
+			if val, err := i.c.opts.codec.Decode(data, i.c.opts.storeOutType); err == nil {
+				i.Lock()
+				i.value = val
+				i.err = nil
+				i.expireAt = expireAt
+				i.refreshing = false
+				firstLoad := !i.initialed
+				if firstLoad {
+					i.initialed = true
+				}
+				i.Unlock()
+				if firstLoad {
+					close(i.initialCh)
+				}
+				i.fireCallbacks(old, val, nil)
+				return
+			}
+		}
+	}
+
 	// load data with fn
+	start := time.Now()
 	val, err := i.loadData()
+	i.c.recordLoadLatency(time.Since(start))
+	if i.c.breaker != nil && err == nil {
+		i.c.breaker.Accept()
+	}
+
+	i.Lock()
 	// don't cache response when underlying resouce exhausted
 	if err != ErrResourceExhausted {
 		i.value = val
 		i.err = err
 	}
-
-	i.expireAt = time.Now().Add(i.c.ttl)
+	newVal := i.value
+	i.expireAt = time.Now().Add(i.cacheTTL(err))
 	// reset refresh flag
 	i.refreshing = false
 	// set initialed flag
-	if !i.initialed {
+	firstLoad := !i.initialed
+	if firstLoad {
 		i.initialed = true
+	}
+	i.Unlock()
+
+	if err == nil {
+		if store := i.c.opts.store; store != nil {
+			if data, encErr := i.c.opts.codec.Encode(val); encErr == nil {
+				store.Set(i.key, data, time.Now().Add(i.c.ttl))
+			}
+		}
+	}
+	if firstLoad {
 		close(i.initialCh)
 	}
+	i.fireCallbacks(old, newVal, err)
+}
+
+// cacheTTL returns how long to cache the current value for. A non-nil err
+// uses the container's negativeTTL, if one is configured, so a failing fn
+// doesn't get cached for as long as a successful call would be.
+func (i *item) cacheTTL(err error) time.Duration {
+	if err != nil && i.c.opts.negativeTTL > 0 {
+		return i.c.opts.negativeTTL
+	}
+	return i.c.ttl
+}
+
+// fireCallbacks invokes the container's OnRefresh/OnError hooks, if any.
+// refresh already runs in its own background goroutine without holding
+// i.Lock, so it's safe to call these synchronously.
+func (i *item) fireCallbacks(old, new interface{}, err error) {
+	opts := i.c.opts
+	if opts.onRefresh != nil {
+		opts.onRefresh(i.key, old, new, err)
+	}
+	if err != nil && opts.onError != nil {
+		opts.onError(i.key, err)
+	}
 }
 
 // loadData is used to load data with fn and params
@@ -0,0 +1,115 @@
+package lcache
+
+import "container/list"
+
+// lfuEntry is a single cached entry tracked by the LFU policy.
+type lfuEntry struct {
+	key    string
+	itm    *item
+	freq   int
+	bucket *list.Element // the freqBucket node in shard.freqBuckets currently holding this entry
+}
+
+// freqBucket groups all entries that share the same access frequency, so
+// that both a hit (bump frequency) and an eviction (drop the least frequent,
+// least recently used entry) run in O(1).
+type freqBucket struct {
+	freq    int
+	entries *list.List // of *lfuEntry, ordered by how recently they joined this bucket
+}
+
+// getLockedLFU returns the item for key, inserting a new one and evicting
+// the least-frequently-used entry if the shard is over capacity. The
+// evicted item, if any, is returned alongside it.
+func (s *shard) getLockedLFU(c *Container, params []interface{}, key string) (*item, *item) {
+	if entryEl, ok := s.lfuElements[key]; ok {
+		s.touchLFU(key)
+		return entryEl.Value.(*lfuEntry).itm, nil
+	}
+
+	itm := newItem(c, key, params)
+	entry := &lfuEntry{key: key, itm: itm, freq: 1}
+
+	front := s.freqBuckets.Front()
+	var bucketEl *list.Element
+	if front != nil && front.Value.(*freqBucket).freq == 1 {
+		bucketEl = front
+	} else {
+		bucketEl = s.freqBuckets.PushFront(&freqBucket{freq: 1, entries: list.New()})
+	}
+	entry.bucket = bucketEl
+	entryEl := bucketEl.Value.(*freqBucket).entries.PushBack(entry)
+
+	// copy on write
+	lfuElements := make(map[string]*list.Element, len(s.lfuElements)+1)
+	for k, v := range s.lfuElements {
+		lfuElements[k] = v
+	}
+	lfuElements[key] = entryEl
+	s.lfuElements = lfuElements
+
+	var evicted *item
+	if len(s.lfuElements) > s.opts.capacity {
+		evicted = s.removeLeastFrequentElement()
+	}
+	return itm, evicted
+}
+
+// touchLFU bumps the frequency of key by one, moving its entry into the
+// next bucket (creating one if it doesn't already exist).
+func (s *shard) touchLFU(key string) {
+	entryEl, ok := s.lfuElements[key]
+	if !ok {
+		return
+	}
+	entry := entryEl.Value.(*lfuEntry)
+	bucketEl := entry.bucket
+	bucket := bucketEl.Value.(*freqBucket)
+	bucket.entries.Remove(entryEl)
+
+	newFreq := entry.freq + 1
+	nextBucketEl := bucketEl.Next()
+	var newBucketEl *list.Element
+	if nextBucketEl != nil && nextBucketEl.Value.(*freqBucket).freq == newFreq {
+		newBucketEl = nextBucketEl
+	} else {
+		newBucketEl = s.freqBuckets.InsertAfter(&freqBucket{freq: newFreq, entries: list.New()}, bucketEl)
+	}
+
+	entry.freq = newFreq
+	entry.bucket = newBucketEl
+	newEntryEl := newBucketEl.Value.(*freqBucket).entries.PushBack(entry)
+	s.lfuElements[key] = newEntryEl
+
+	if bucket.entries.Len() == 0 {
+		s.freqBuckets.Remove(bucketEl)
+	}
+}
+
+// removeLeastFrequentElement evicts and returns the oldest entry in the
+// lowest-frequency bucket.
+func (s *shard) removeLeastFrequentElement() *item {
+	bucketEl := s.freqBuckets.Front()
+	if bucketEl == nil {
+		return nil
+	}
+	entryEl := bucketEl.Value.(*freqBucket).entries.Front()
+	if entryEl == nil {
+		return nil
+	}
+	return s.removeLFUElement(entryEl)
+}
+
+// removeLFUElement removes a given lfu entry element from the shard and
+// returns the item it held.
+func (s *shard) removeLFUElement(entryEl *list.Element) *item {
+	entry := entryEl.Value.(*lfuEntry)
+	bucketEl := entry.bucket
+	bucket := bucketEl.Value.(*freqBucket)
+	bucket.entries.Remove(entryEl)
+	if bucket.entries.Len() == 0 {
+		s.freqBuckets.Remove(bucketEl)
+	}
+	delete(s.lfuElements, entry.key)
+	return entry.itm
+}
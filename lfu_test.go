@@ -0,0 +1,61 @@
+package lcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvictContainerLFU(t *testing.T) {
+	fn := func(x, y int) (interface{}, error) {
+		return "hello, world", nil
+	}
+	c, _ := New(fn, 300*time.Millisecond, WithCapacity(2), WithLFU())
+
+	// first
+	c.Get(1, 2)
+	if c.Len() != 1 {
+		t.Errorf("container expected length is 1, but got %d", c.Len())
+	}
+
+	// second
+	c.Get(2, 3)
+	if c.Len() != 2 {
+		t.Errorf("container expected length is 2, but got %d", c.Len())
+	}
+
+	// third, forces an eviction
+	c.Get(3, 4)
+	if c.Len() != 2 {
+		t.Errorf("container expected length is 2, but got %d", c.Len())
+	}
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	fn := func(x int) (interface{}, error) {
+		return x, nil
+	}
+	c, _ := New(fn, time.Minute, WithCapacity(2), WithLFU())
+
+	// key 1 gets hit repeatedly, key 2 only once.
+	c.Get(1)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+
+	// key 3 is inserted later but 2 is still less frequently used than 1,
+	// so 2 must be the one evicted.
+	c.Get(3)
+
+	if c.Len() != 2 {
+		t.Fatalf("container expected length is 2, but got %d", c.Len())
+	}
+	if c.Remove(2) {
+		t.Errorf("expected key 2 to have been evicted")
+	}
+	if !c.Remove(1) {
+		t.Errorf("expected key 1 to still be cached")
+	}
+	if !c.Remove(3) {
+		t.Errorf("expected key 3 to still be cached")
+	}
+}
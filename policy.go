@@ -0,0 +1,341 @@
+package lcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Policy decides which key to evict from a container once it grows past
+// capacity. A Policy only tracks keys; the container itself still owns the
+// value storage, so implementations never see or touch an item's value.
+//
+// Policy implementations are expected to be safe for concurrent use on
+// their own, since WithPolicy installs the same instance across the
+// container (WithShards is ignored when a Policy is supplied, since the
+// policy is itself the single source of truth for eviction order).
+type Policy interface {
+	// OnAccess is invoked when key is read and already cached.
+	OnAccess(key string)
+	// OnInsert is invoked when key is newly inserted.
+	OnInsert(key string)
+	// Evict returns a key that should be evicted, if the policy is
+	// currently over capacity.
+	Evict() (key string, ok bool)
+	// Remove stops tracking key, e.g. after an explicit Remove or Purge.
+	Remove(key string)
+}
+
+// WithPolicy returns a Option which drives eviction through policy instead
+// of the built-in WithLRU/WithLFU algorithms. See NewLRUPolicy,
+// NewSievePolicy and NewTwoQPolicy.
+func WithPolicy(policy Policy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// NewContainerWithPolicy is a convenience wrapper around New that installs
+// policy as the container's eviction Policy.
+func NewContainerWithPolicy(fn interface{}, ttl time.Duration, policy Policy, opt ...Option) (*Container, error) {
+	return New(fn, ttl, append(opt, WithPolicy(policy))...)
+}
+
+// lruPolicy is the classic least-recently-used Policy.
+type lruPolicy struct {
+	mu        sync.Mutex
+	capacity  int
+	evictList *list.List
+	elements  map[string]*list.Element
+}
+
+// NewLRUPolicy returns a Policy that evicts the least-recently-used key
+// once more than capacity keys are tracked.
+func NewLRUPolicy(capacity int) Policy {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &lruPolicy{
+		capacity:  capacity,
+		evictList: list.New(),
+		elements:  make(map[string]*list.Element),
+	}
+}
+
+func (p *lruPolicy) OnAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ent, ok := p.elements[key]; ok {
+		p.evictList.MoveToFront(ent)
+	}
+}
+
+func (p *lruPolicy) OnInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.elements[key]; ok {
+		return
+	}
+	p.elements[key] = p.evictList.PushFront(key)
+}
+
+func (p *lruPolicy) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.evictList.Len() <= p.capacity {
+		return "", false
+	}
+	ent := p.evictList.Back()
+	if ent == nil {
+		return "", false
+	}
+	key := ent.Value.(string)
+	p.evictList.Remove(ent)
+	delete(p.elements, key)
+	return key, true
+}
+
+func (p *lruPolicy) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ent, ok := p.elements[key]; ok {
+		p.evictList.Remove(ent)
+		delete(p.elements, key)
+	}
+}
+
+// sieveEntry is a single tracked key in a sievePolicy.
+type sieveEntry struct {
+	key     string
+	visited bool
+}
+
+// sievePolicy implements SIEVE, as adopted by dnscrypt-proxy: a single FIFO
+// list with a "hand" that walks from tail toward head looking for the
+// first not-recently-visited entry to evict.
+type sievePolicy struct {
+	mu       sync.Mutex
+	capacity int
+	list     *list.List
+	elements map[string]*list.Element
+	hand     *list.Element
+}
+
+// NewSievePolicy returns a Policy implementing SIEVE, which tends to beat
+// LRU on scan-heavy workloads without the complexity (or patent) of ARC.
+func NewSievePolicy(capacity int) Policy {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &sievePolicy{
+		capacity: capacity,
+		list:     list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+func (p *sievePolicy) OnAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ent, ok := p.elements[key]; ok {
+		ent.Value.(*sieveEntry).visited = true
+	}
+}
+
+func (p *sievePolicy) OnInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.elements[key]; ok {
+		return
+	}
+	p.elements[key] = p.list.PushFront(&sieveEntry{key: key})
+}
+
+func (p *sievePolicy) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.list.Len() <= p.capacity {
+		return "", false
+	}
+
+	hand := p.hand
+	if hand == nil {
+		hand = p.list.Back()
+	}
+	for hand != nil && hand.Value.(*sieveEntry).visited {
+		hand.Value.(*sieveEntry).visited = false
+		prev := hand.Prev()
+		if prev == nil {
+			prev = p.list.Back()
+		}
+		hand = prev
+	}
+	if hand == nil {
+		return "", false
+	}
+
+	key := hand.Value.(*sieveEntry).key
+	prev := hand.Prev()
+	p.list.Remove(hand)
+	delete(p.elements, key)
+	if prev == nil {
+		prev = p.list.Back()
+	}
+	p.hand = prev
+	return key, true
+}
+
+func (p *sievePolicy) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ent, ok := p.elements[key]
+	if !ok {
+		return
+	}
+	if p.hand == ent {
+		prev := ent.Prev()
+		if prev == nil {
+			prev = p.list.Back()
+		}
+		if prev == ent {
+			prev = nil
+		}
+		p.hand = prev
+	}
+	p.list.Remove(ent)
+	delete(p.elements, key)
+}
+
+// twoQPolicy implements a simplified 2Q: recent misses land in A1in, keys
+// evicted from A1in leave a valueless "ghost" behind in A1out, and a ghost
+// hit promotes the key straight into Am, the frequent set.
+type twoQPolicy struct {
+	mu       sync.Mutex
+	a1inCap  int
+	a1outCap int
+
+	a1inList *list.List
+	a1in     map[string]*list.Element
+
+	a1outList *list.List
+	a1out     map[string]*list.Element
+
+	amList *list.List
+	am     map[string]*list.Element
+}
+
+// NewTwoQPolicy returns a Policy implementing 2Q, splitting capacity into a
+// recent-insertion queue (A1in, ~25%), a ghost queue of evicted keys used
+// only to detect re-references (A1out, ~50%), and a frequent queue (Am)
+// for keys that have proven themselves with a second access.
+func NewTwoQPolicy(capacity int) Policy {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	a1inCap := capacity / 4
+	if a1inCap < 1 {
+		a1inCap = 1
+	}
+	a1outCap := capacity / 2
+	if a1outCap < 1 {
+		a1outCap = 1
+	}
+	return &twoQPolicy{
+		a1inCap:   a1inCap,
+		a1outCap:  a1outCap,
+		a1inList:  list.New(),
+		a1in:      make(map[string]*list.Element),
+		a1outList: list.New(),
+		a1out:     make(map[string]*list.Element),
+		amList:    list.New(),
+		am:        make(map[string]*list.Element),
+	}
+}
+
+func (p *twoQPolicy) OnAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.a1in[key]; ok {
+		// hits in A1in stay put
+		return
+	}
+	if ent, ok := p.am[key]; ok {
+		p.amList.MoveToFront(ent)
+	}
+}
+
+func (p *twoQPolicy) OnInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ent, ok := p.a1out[key]; ok {
+		// ghost hit: promote straight to Am
+		p.a1outList.Remove(ent)
+		delete(p.a1out, key)
+		p.am[key] = p.amList.PushFront(key)
+		return
+	}
+	if _, ok := p.a1in[key]; ok {
+		return
+	}
+	if _, ok := p.am[key]; ok {
+		return
+	}
+	p.a1in[key] = p.a1inList.PushFront(key)
+}
+
+func (p *twoQPolicy) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.a1inList.Len() > p.a1inCap {
+		ent := p.a1inList.Back()
+		key := ent.Value.(string)
+		p.a1inList.Remove(ent)
+		delete(p.a1in, key)
+		p.pushGhost(key)
+		return key, true
+	}
+
+	realCap := p.a1inCap + p.a1outCap // Am's share of the visible capacity
+	if p.amList.Len() > realCap {
+		ent := p.amList.Back()
+		if ent == nil {
+			return "", false
+		}
+		key := ent.Value.(string)
+		p.amList.Remove(ent)
+		delete(p.am, key)
+		return key, true
+	}
+	return "", false
+}
+
+// pushGhost records key as a recently-evicted A1in entry, bounding A1out to
+// a1outCap entries.
+func (p *twoQPolicy) pushGhost(key string) {
+	p.a1out[key] = p.a1outList.PushFront(key)
+	if p.a1outList.Len() > p.a1outCap {
+		old := p.a1outList.Back()
+		if old != nil {
+			delete(p.a1out, old.Value.(string))
+			p.a1outList.Remove(old)
+		}
+	}
+}
+
+func (p *twoQPolicy) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ent, ok := p.a1in[key]; ok {
+		p.a1inList.Remove(ent)
+		delete(p.a1in, key)
+	}
+	if ent, ok := p.am[key]; ok {
+		p.amList.Remove(ent)
+		delete(p.am, key)
+	}
+	if ent, ok := p.a1out[key]; ok {
+		p.a1outList.Remove(ent)
+		delete(p.a1out, key)
+	}
+}
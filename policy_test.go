@@ -0,0 +1,90 @@
+package lcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	calls := make(map[int]int)
+	fn := func(id int) (int, error) {
+		calls[id]++
+		return id, nil
+	}
+	c, err := New(fn, time.Hour, WithPolicy(NewLRUPolicy(2)))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1)
+	c.Get(2)
+	c.Get(1) // keep 1 warm
+	c.Get(3) // evicts 2, the least-recently-used
+
+	if c.Len() != 2 {
+		t.Fatalf("expected length 2, got %d", c.Len())
+	}
+	c.Get(2)
+	if calls[2] != 2 {
+		t.Errorf("expected key 2 to have been evicted and reloaded, got %d calls", calls[2])
+	}
+}
+
+func TestPolicySieveEvictsUnvisitedEntry(t *testing.T) {
+	calls := make(map[int]int)
+	fn := func(id int) (int, error) {
+		calls[id]++
+		return id, nil
+	}
+	c, err := New(fn, time.Hour, WithPolicy(NewSievePolicy(2)))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1)
+	c.Get(2)
+	c.Get(1) // mark 1 as visited, 2 stays unvisited
+	c.Get(3) // should evict 2, not 1
+
+	c.Get(1)
+	c.Get(2)
+	if calls[1] != 1 {
+		t.Errorf("expected key 1 to have survived eviction, got %d calls", calls[1])
+	}
+	if calls[2] != 2 {
+		t.Errorf("expected key 2 to have been evicted and reloaded, got %d calls", calls[2])
+	}
+}
+
+func TestPolicyTwoQPromotesGhostHitToAm(t *testing.T) {
+	policy := NewTwoQPolicy(8) // a1in=2, a1out=4
+
+	policy.OnInsert("a")
+	policy.OnInsert("b")
+	policy.OnInsert("c") // puts a1in over capacity; the next Evict demotes "a" to the ghost queue
+
+	if key, ok := policy.Evict(); !ok || key != "a" {
+		t.Fatalf("expected a1in to evict %q, got %q (ok=%v)", "a", key, ok)
+	}
+
+	// a re-reference of "a" should be treated as a ghost hit and promoted to Am.
+	policy.OnInsert("a")
+	policy.OnAccess("a")
+	if _, ok := policy.Evict(); ok {
+		t.Fatalf("did not expect an eviction immediately after a ghost promotion")
+	}
+}
+
+func TestNewContainerWithPolicy(t *testing.T) {
+	fn := func(id int) (int, error) { return id, nil }
+	c, err := NewContainerWithPolicy(fn, time.Hour, NewLRUPolicy(4))
+	if err != nil {
+		t.Fatalf("NewContainerWithPolicy failed: %v", err)
+	}
+	if _, err := c.Get(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected length 1, got %d", c.Len())
+	}
+}
@@ -0,0 +1,421 @@
+package lcache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// shard holds one slice of the container's keyspace behind its own lock, so
+// that Get/Remove/Purge calls against unrelated keys never contend with each
+// other.
+type shard struct {
+	sync.RWMutex
+	opts *options
+
+	elements  map[string]*list.Element // lru related elements
+	evictList *list.List
+
+	lfuElements map[string]*list.Element // key -> element of the bucket's inner list
+	freqBuckets *list.List               // lfu buckets, ordered ascending by frequency
+
+	items map[string]*item
+}
+
+// newShard constructs an empty shard using the given container options.
+func newShard(opts *options) *shard {
+	s := &shard{opts: opts}
+	switch {
+	case opts.policy != nil:
+		s.items = make(map[string]*item)
+	case opts.enableLFU:
+		s.freqBuckets = list.New()
+		s.lfuElements = make(map[string]*list.Element)
+	case opts.enableLRU:
+		s.evictList = list.New()
+		s.elements = make(map[string]*list.Element)
+	default:
+		s.items = make(map[string]*item)
+	}
+	return s
+}
+
+// hashKey hashes a cache key for shard selection.
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// notifyEvict records reason in the container's Stats/MetricsSink and fires
+// the OnEvict callback for itm, if one is registered. It must be called
+// without the shard lock held.
+func (s *shard) notifyEvict(itm *item, reason EvictReason) {
+	if itm == nil {
+		return
+	}
+	s.opts.stats.recordEvict(reason)
+	if s.opts.metricsSink != nil {
+		s.opts.metricsSink.IncEvict(reason)
+	}
+	if s.opts.onEvict != nil {
+		s.opts.onEvict(itm.key, itm.params, itm.value, reason)
+	}
+}
+
+// recordHit records a cache hit in the container's Stats/MetricsSink.
+func (s *shard) recordHit() {
+	s.opts.stats.recordHit()
+	if s.opts.metricsSink != nil {
+		s.opts.metricsSink.IncHit()
+	}
+}
+
+// recordMiss records a cache miss in the container's Stats/MetricsSink.
+func (s *shard) recordMiss() {
+	s.opts.stats.recordMiss()
+	if s.opts.metricsSink != nil {
+		s.opts.metricsSink.IncMiss()
+	}
+}
+
+// recordAccess records a hit or a miss for an entry found in the shard,
+// depending on whether it's expired. An expired entry is still handed back
+// to the caller -- item.Value already serves its stale value while
+// refreshing in the background -- but the access itself counts as a miss.
+func (s *shard) recordAccess(expired bool) {
+	if expired {
+		s.recordMiss()
+		return
+	}
+	s.recordHit()
+}
+
+// has reports whether key is already cached, without creating or touching
+// an entry. The lookup takes the shard's read lock, since removal paths
+// (sweepExpired, removeElement, removeLFUElement, ...) mutate the
+// underlying maps in place under the write lock rather than replacing them
+// wholesale.
+func (s *shard) has(key string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	switch {
+	case s.opts.policy != nil:
+		_, ok := s.items[key]
+		return ok
+	case s.opts.enableLFU:
+		_, ok := s.lfuElements[key]
+		return ok
+	case s.opts.enableLRU:
+		_, ok := s.elements[key]
+		return ok
+	default:
+		_, ok := s.items[key]
+		return ok
+	}
+}
+
+// get returns the item for key, creating and inserting it via c/params if
+// it isn't already cached. An expired entry is a hit as far as the map/list
+// bookkeeping is concerned -- it's handed straight back rather than evicted
+// and recreated, so item.Value can serve its stale value while refreshing
+// in the background instead of every caller blocking on a fresh load; it
+// only counts as a miss for stats. The initial lookup takes the shard's
+// read lock for the same reason has does; the write lock is taken only to
+// insert, or to update recency/frequency order on a hit in a policy, LFU or
+// LRU shard.
+func (s *shard) get(c *Container, params []interface{}, key string) *item {
+	switch {
+	case s.opts.policy != nil:
+		s.RLock()
+		itm, ok := s.items[key]
+		s.RUnlock()
+		if ok {
+			s.Lock()
+			s.opts.policy.OnAccess(key)
+			s.Unlock()
+			s.recordAccess(itm.Expired())
+			return itm
+		}
+		s.Lock()
+		itm, evicted := s.getLockedPolicy(c, params, key)
+		s.Unlock()
+		s.notifyEvict(evicted, EvictCapacity)
+		s.recordMiss()
+		return itm
+	case s.opts.enableLFU:
+		s.RLock()
+		entryEl, ok := s.lfuElements[key]
+		var itm *item
+		if ok {
+			itm = entryEl.Value.(*lfuEntry).itm
+		}
+		s.RUnlock()
+		if ok {
+			s.Lock()
+			s.touchLFU(key)
+			s.Unlock()
+			s.recordAccess(itm.Expired())
+			return itm
+		}
+		s.Lock()
+		itm, evicted := s.getLockedLFU(c, params, key)
+		s.Unlock()
+		s.notifyEvict(evicted, EvictCapacity)
+		s.recordMiss()
+		return itm
+	case s.opts.enableLRU:
+		s.RLock()
+		ent, ok := s.elements[key]
+		s.RUnlock()
+		if ok {
+			s.Lock()
+			s.evictList.MoveToFront(ent)
+			s.Unlock()
+			itm := ent.Value.(*item)
+			s.recordAccess(itm.Expired())
+			return itm
+		}
+		s.Lock()
+		ent, evicted := s.getLockedLRU(c, params, key)
+		s.Unlock()
+		s.notifyEvict(evicted, EvictCapacity)
+		s.recordMiss()
+		return ent.Value.(*item)
+	default:
+		s.RLock()
+		itm, ok := s.items[key]
+		s.RUnlock()
+		if ok {
+			s.recordAccess(itm.Expired())
+			return itm
+		}
+		s.Lock()
+		itm = s.getLocked(c, params, key)
+		s.Unlock()
+		s.recordMiss()
+		return itm
+	}
+}
+
+// getLockedPolicy returns the item for key, inserting a new one if needed
+// and consulting s.opts.policy for both ordering and eviction. It also
+// returns the item evicted by capacity pressure, if any.
+func (s *shard) getLockedPolicy(c *Container, params []interface{}, key string) (*item, *item) {
+	if itm, ok := s.items[key]; ok {
+		s.opts.policy.OnAccess(key)
+		return itm, nil
+	}
+
+	itm := newItem(c, key, params)
+	// copy on write
+	items := make(map[string]*item, len(s.items)+1)
+	for k, v := range s.items {
+		items[k] = v
+	}
+	items[key] = itm
+
+	s.opts.policy.OnInsert(key)
+	var evicted *item
+	if evictKey, ok := s.opts.policy.Evict(); ok {
+		if e, exists := items[evictKey]; exists {
+			evicted = e
+			delete(items, evictKey)
+		}
+	}
+	s.items = items
+
+	return itm, evicted
+}
+
+func (s *shard) getLocked(c *Container, params []interface{}, key string) *item {
+	if itm, ok := s.items[key]; ok {
+		return itm
+	}
+
+	itm := newItem(c, key, params)
+	// copy on write
+	items := make(map[string]*item, len(s.items)+1)
+	for k, v := range s.items {
+		items[k] = v
+	}
+	items[key] = itm
+	s.items = items
+
+	return itm
+}
+
+// getLockedLRU returns the element for key, inserting a new one if needed.
+// It also returns the item evicted by capacity pressure, if any.
+func (s *shard) getLockedLRU(c *Container, params []interface{}, key string) (*list.Element, *item) {
+	if ent, ok := s.elements[key]; ok {
+		s.evictList.MoveToFront(ent)
+		return ent, nil
+	}
+
+	itm := newItem(c, key, params)
+	ent := s.evictList.PushFront(itm)
+
+	// copy on write
+	elements := make(map[string]*list.Element, len(s.elements)+1)
+	for k, v := range s.elements {
+		elements[k] = v
+	}
+	elements[key] = ent
+	s.elements = elements
+
+	var evicted *item
+	if s.evictList.Len() > s.opts.capacity {
+		evicted = s.removeOldestElement()
+	}
+	return ent, evicted
+}
+
+// removeOldestElement removes and returns the oldest item from the shard.
+func (s *shard) removeOldestElement() *item {
+	ent := s.evictList.Back()
+	if ent == nil {
+		return nil
+	}
+	return s.removeElement(ent)
+}
+
+// removeElement removes a given list element from the shard and returns
+// the item it held.
+func (s *shard) removeElement(e *list.Element) *item {
+	s.evictList.Remove(e)
+	itm := e.Value.(*item)
+	delete(s.elements, itm.key)
+	return itm
+}
+
+// purge completely clears the shard.
+func (s *shard) purge() {
+	s.Lock()
+	var evicted []*item
+	switch {
+	case s.opts.policy != nil:
+		for key, itm := range s.items {
+			evicted = append(evicted, itm)
+			s.opts.policy.Remove(key)
+		}
+		s.items = make(map[string]*item)
+	case s.opts.enableLFU:
+		for _, ent := range s.lfuElements {
+			evicted = append(evicted, ent.Value.(*lfuEntry).itm)
+		}
+		for key := range s.lfuElements {
+			delete(s.lfuElements, key)
+		}
+		s.freqBuckets.Init()
+	case s.opts.enableLRU:
+		for _, ent := range s.elements {
+			evicted = append(evicted, ent.Value.(*item))
+		}
+		for key := range s.elements {
+			delete(s.elements, key)
+		}
+		s.evictList.Init()
+	default:
+		for _, itm := range s.items {
+			evicted = append(evicted, itm)
+		}
+		for key := range s.items {
+			delete(s.items, key)
+		}
+	}
+	s.Unlock()
+
+	for _, itm := range evicted {
+		s.notifyEvict(itm, EvictPurge)
+	}
+}
+
+// sweepExpired evicts every entry in the shard whose TTL has passed.
+func (s *shard) sweepExpired() {
+	s.Lock()
+	var evicted []*item
+	switch {
+	case s.opts.policy != nil:
+		for key, itm := range s.items {
+			if itm.Expired() {
+				evicted = append(evicted, itm)
+				delete(s.items, key)
+				s.opts.policy.Remove(key)
+			}
+		}
+	case s.opts.enableLFU:
+		for _, entryEl := range s.lfuElements {
+			itm := entryEl.Value.(*lfuEntry).itm
+			if itm.Expired() {
+				evicted = append(evicted, s.removeLFUElement(entryEl))
+			}
+		}
+	case s.opts.enableLRU:
+		for _, ent := range s.elements {
+			if ent.Value.(*item).Expired() {
+				evicted = append(evicted, s.removeElement(ent))
+			}
+		}
+	default:
+		for key, itm := range s.items {
+			if itm.Expired() {
+				evicted = append(evicted, itm)
+				delete(s.items, key)
+			}
+		}
+	}
+	s.Unlock()
+
+	for _, itm := range evicted {
+		s.notifyEvict(itm, EvictExpired)
+	}
+}
+
+// remove removes key from the shard, returning whether it was present.
+func (s *shard) remove(key string) bool {
+	s.Lock()
+	var evicted *item
+	switch {
+	case s.opts.policy != nil:
+		if itm, ok := s.items[key]; ok {
+			evicted = itm
+			delete(s.items, key)
+			s.opts.policy.Remove(key)
+		}
+	case s.opts.enableLFU:
+		if entryEl, ok := s.lfuElements[key]; ok {
+			evicted = entryEl.Value.(*lfuEntry).itm
+			s.removeLFUElement(entryEl)
+		}
+	case s.opts.enableLRU:
+		if ent, ok := s.elements[key]; ok {
+			evicted = s.removeElement(ent)
+		}
+	default:
+		if itm, ok := s.items[key]; ok {
+			evicted = itm
+			delete(s.items, key)
+		}
+	}
+	s.Unlock()
+
+	s.notifyEvict(evicted, EvictManual)
+	return evicted != nil
+}
+
+// len returns the number of items held by the shard.
+func (s *shard) len() int {
+	s.RLock()
+	defer s.RUnlock()
+	switch {
+	case s.opts.policy != nil:
+		return len(s.items)
+	case s.opts.enableLFU:
+		return len(s.lfuElements)
+	case s.opts.enableLRU:
+		return len(s.elements)
+	default:
+		return len(s.items)
+	}
+}
@@ -0,0 +1,55 @@
+package lcache
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextPowerOfTwo(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 4: 4, 5: 8, 16: 16, 17: 32}
+	for in, want := range cases {
+		if got := nextPowerOfTwo(in); got != want {
+			t.Errorf("nextPowerOfTwo(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestShardedContainer(t *testing.T) {
+	fn := func(x int) (interface{}, error) {
+		return x, nil
+	}
+	c, _ := New(fn, time.Minute, WithShards(8))
+	if len(c.shards) != 8 {
+		t.Fatalf("expected 8 shards, got %d", len(c.shards))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(i)
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() != 100 {
+		t.Errorf("expected 100 items, got %d", c.Len())
+	}
+}
+
+func BenchmarkConcurrentGet(b *testing.B) {
+	fn := func(x int) (interface{}, error) {
+		return x, nil
+	}
+	c, _ := New(fn, time.Minute, WithShards(64), WithLRU(), WithCapacity(10000))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Get(rand.Intn(10000))
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package lcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentMissesOnSameKeyShareOneLoad(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	fn := func(id int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return id, nil
+	}
+	c, err := New(fn, time.Hour)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(1)
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond) // let every goroutine reach the same in-flight item
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected fn to be called exactly once, got %d", got)
+	}
+}
+
+func TestConcurrentMissesOnDifferentKeysRunInParallel(t *testing.T) {
+	release := make(chan struct{})
+	var entered int32
+	fn := func(id int) (int, error) {
+		atomic.AddInt32(&entered, 1)
+		<-release
+		return id, nil
+	}
+	c, err := New(fn, time.Hour)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	done := make(chan struct{}, 2)
+	go func() { c.Get(1); done <- struct{}{} }()
+	go func() { c.Get(2); done <- struct{}{} }()
+
+	select {
+	case <-done:
+		t.Fatal("a Get returned before release was closed, both should be blocked in fn")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if got := atomic.LoadInt32(&entered); got != 2 {
+		t.Errorf("expected both loads to be inside fn at once, got %d", got)
+	}
+
+	close(release)
+	<-done
+	<-done
+}
+
+func TestWithNegativeTTLCachesErrorsBriefly(t *testing.T) {
+	wantErr := errors.New("boom")
+	var calls int32
+	fn := func(id int) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			return 0, wantErr
+		}
+		return id, nil
+	}
+	c, err := New(fn, time.Hour, WithNegativeTTL(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, gotErr := c.Get(1)
+	if gotErr != wantErr {
+		t.Fatalf("expected %v, got %v", wantErr, gotErr)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	// the entry is expired, so this Get kicks off a background refresh;
+	// give it a moment to land before checking the reloaded value.
+	c.Get(1)
+	time.Sleep(20 * time.Millisecond)
+
+	val, gotErr := c.Get(1)
+	if gotErr != nil || val != 1 {
+		t.Fatalf("expected the negative cache entry to have expired and reloaded, got (%v, %v)", val, gotErr)
+	}
+}
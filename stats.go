@@ -0,0 +1,113 @@
+package lcache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSink lets callers bridge a container's counters to an external
+// metrics system (Prometheus, OpenTelemetry, ...) without this module
+// depending on either. Every event recorded in Stats is also forwarded to
+// sink, if one is configured via WithMetricsSink.
+type MetricsSink interface {
+	IncHit()
+	IncMiss()
+	IncEvict(reason EvictReason)
+	ObserveLoadLatency(d time.Duration)
+}
+
+// WithMetricsSink returns a Option which forwards every stats event to sink
+// as it happens, in addition to the counters available via Container.Stats.
+func WithMetricsSink(sink MetricsSink) Option {
+	return func(o *options) {
+		o.metricsSink = sink
+	}
+}
+
+// Stats is a point-in-time snapshot of a container's counters, returned by
+// Container.Stats.
+type Stats struct {
+	Hits           uint64
+	Misses         uint64
+	CapacityEvicts uint64
+	ExpiredEvicts  uint64
+	ManualEvicts   uint64
+	PurgeEvicts    uint64
+
+	// LoadLatencyEWMA is an exponentially-weighted moving average of how
+	// long fn took, across every key. It only tracks real fn calls -- a
+	// secondary-store hit or a circuit-breaker short-circuit don't count.
+	LoadLatencyEWMA time.Duration
+}
+
+// loadLatencyEWMAWeight is the smoothing factor applied to each new
+// latency sample; higher reacts faster to recent calls, lower is steadier.
+const loadLatencyEWMAWeight = 0.2
+
+// containerStats holds the live, atomically-updated counters behind
+// Container.Stats and Container.ResetStats.
+type containerStats struct {
+	hits            uint64
+	misses          uint64
+	capacityEvicts  uint64
+	expiredEvicts   uint64
+	manualEvicts    uint64
+	purgeEvicts     uint64
+	loadLatencyEWMA int64 // nanoseconds, stored as int64 for atomic access
+}
+
+func (s *containerStats) recordHit() {
+	atomic.AddUint64(&s.hits, 1)
+}
+
+func (s *containerStats) recordMiss() {
+	atomic.AddUint64(&s.misses, 1)
+}
+
+func (s *containerStats) recordEvict(reason EvictReason) {
+	switch reason {
+	case EvictCapacity:
+		atomic.AddUint64(&s.capacityEvicts, 1)
+	case EvictExpired:
+		atomic.AddUint64(&s.expiredEvicts, 1)
+	case EvictManual:
+		atomic.AddUint64(&s.manualEvicts, 1)
+	case EvictPurge:
+		atomic.AddUint64(&s.purgeEvicts, 1)
+	}
+}
+
+func (s *containerStats) recordLoadLatency(d time.Duration) {
+	for {
+		old := atomic.LoadInt64(&s.loadLatencyEWMA)
+		next := int64(d)
+		if old != 0 {
+			next = old + int64(loadLatencyEWMAWeight*float64(int64(d)-old))
+		}
+		if atomic.CompareAndSwapInt64(&s.loadLatencyEWMA, old, next) {
+			return
+		}
+	}
+}
+
+func (s *containerStats) snapshot() Stats {
+	return Stats{
+		Hits:            atomic.LoadUint64(&s.hits),
+		Misses:          atomic.LoadUint64(&s.misses),
+		CapacityEvicts:  atomic.LoadUint64(&s.capacityEvicts),
+		ExpiredEvicts:   atomic.LoadUint64(&s.expiredEvicts),
+		ManualEvicts:    atomic.LoadUint64(&s.manualEvicts),
+		PurgeEvicts:     atomic.LoadUint64(&s.purgeEvicts),
+		LoadLatencyEWMA: time.Duration(atomic.LoadInt64(&s.loadLatencyEWMA)),
+	}
+}
+
+func (s *containerStats) reset() {
+	atomic.StoreUint64(&s.hits, 0)
+	atomic.StoreUint64(&s.misses, 0)
+	atomic.StoreUint64(&s.capacityEvicts, 0)
+	atomic.StoreUint64(&s.expiredEvicts, 0)
+	atomic.StoreUint64(&s.manualEvicts, 0)
+	atomic.StoreUint64(&s.purgeEvicts, 0)
+	atomic.StoreInt64(&s.loadLatencyEWMA, 0)
+}
@@ -0,0 +1,89 @@
+package lcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsTracksHitsMissesAndEvictions(t *testing.T) {
+	fn := func(x int) (int, error) { return x, nil }
+	c, err := New(fn, time.Hour, WithCapacity(1), WithLRU())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1) // miss
+	c.Get(1) // hit
+	c.Get(2) // miss, evicts 1 (capacity)
+
+	st := c.Stats()
+	if st.Hits != 1 {
+		t.Errorf("expected 1 hit, got %d", st.Hits)
+	}
+	if st.Misses != 2 {
+		t.Errorf("expected 2 misses, got %d", st.Misses)
+	}
+	if st.CapacityEvicts != 1 {
+		t.Errorf("expected 1 capacity eviction, got %d", st.CapacityEvicts)
+	}
+
+	c.ResetStats()
+	st = c.Stats()
+	if st.Hits != 0 || st.Misses != 0 || st.CapacityEvicts != 0 {
+		t.Errorf("expected all counters reset, got %+v", st)
+	}
+}
+
+func TestStatsTracksLoadLatency(t *testing.T) {
+	fn := func(x int) (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return x, nil
+	}
+	c, err := New(fn, time.Hour)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1)
+	if c.Stats().LoadLatencyEWMA < time.Millisecond {
+		t.Errorf("expected a load latency of at least 1ms, got %v", c.Stats().LoadLatencyEWMA)
+	}
+}
+
+type recordingSink struct {
+	hits, misses int
+	evicts       []EvictReason
+	latencies    []time.Duration
+}
+
+func (r *recordingSink) IncHit()   { r.hits++ }
+func (r *recordingSink) IncMiss()  { r.misses++ }
+func (r *recordingSink) IncEvict(reason EvictReason) {
+	r.evicts = append(r.evicts, reason)
+}
+func (r *recordingSink) ObserveLoadLatency(d time.Duration) {
+	r.latencies = append(r.latencies, d)
+}
+
+func TestMetricsSinkReceivesEvents(t *testing.T) {
+	sink := &recordingSink{}
+	fn := func(x int) (int, error) { return x, nil }
+	c, err := New(fn, time.Hour, WithCapacity(1), WithLRU(), WithMetricsSink(sink))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	c.Get(1)
+	c.Get(1)
+	c.Get(2) // evicts 1
+
+	if sink.hits != 1 || sink.misses != 2 {
+		t.Errorf("expected (1 hit, 2 misses), got (%d, %d)", sink.hits, sink.misses)
+	}
+	if len(sink.evicts) != 1 || sink.evicts[0] != EvictCapacity {
+		t.Errorf("expected a single EvictCapacity event, got %v", sink.evicts)
+	}
+	if len(sink.latencies) != 2 {
+		t.Errorf("expected a latency observation per load, got %d", len(sink.latencies))
+	}
+}
@@ -0,0 +1,65 @@
+package lcache
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Store is a secondary, typically persistent, cache tier consulted on a
+// miss/expiry before falling back to fn, and written back to after a
+// successful refresh. This lets a Container survive process restarts or
+// share state across replicas (e.g. a filesystem-backed store such as
+// FSStore, or a user-supplied Redis/BoltDB-backed implementation).
+type Store interface {
+	// Get returns the encoded value for key and the time it expires at, or
+	// ok == false if key isn't present.
+	Get(key string) (data []byte, expireAt time.Time, ok bool)
+	// Set stores the encoded value for key, replacing any existing entry.
+	Set(key string, data []byte, expireAt time.Time)
+	// Delete removes key, if present.
+	Delete(key string)
+	// Purge removes everything from the store.
+	Purge()
+}
+
+// Codec encodes/decodes the values a Container caches so they can be
+// written to and read back from a Store.
+type Codec interface {
+	Encode(value interface{}) ([]byte, error)
+	// Decode decodes data into a value of the given type, as returned by fn.
+	Decode(data []byte, outType reflect.Type) (interface{}, error)
+}
+
+// WithSecondaryStore returns a Option which consults store (through codec)
+// on a cache miss or expiry before calling fn, and writes the refreshed
+// value back through to store afterwards. outType must be the concrete type
+// fn returns (e.g. reflect.TypeOf(MyStruct{})): fn is only required to
+// return interface{} at the type-checker level, so codec has no other way
+// to know what concrete type to unmarshal a stored value back into,
+// especially across the process restart this feature exists for, where a
+// fresh Container hasn't called fn even once yet.
+func WithSecondaryStore(store Store, codec Codec, outType reflect.Type) Option {
+	return func(o *options) {
+		o.store = store
+		o.codec = codec
+		o.storeOutType = outType
+	}
+}
+
+// JSONCodec is the default Codec, backed by encoding/json.
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(data []byte, outType reflect.Type) (interface{}, error) {
+	out := reflect.New(outType)
+	if err := json.Unmarshal(data, out.Interface()); err != nil {
+		return nil, err
+	}
+	return out.Elem().Interface(), nil
+}
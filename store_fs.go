@@ -0,0 +1,201 @@
+package lcache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FSStore is a filesystem-backed Store. Entries are sharded across 256
+// subdirectories of baseDir, keyed by a hash of the cache key, so that no
+// single directory ends up holding an unbounded number of files. When a
+// capacity is configured, the least-recently-used entries (by file mtime)
+// are evicted once the store's total size exceeds it.
+type FSStore struct {
+	mu        sync.Mutex
+	baseDir   string
+	capacity  int64 // bytes; 0 means unbounded
+	totalSize int64
+}
+
+// FSStoreOption configures a FSStore.
+type FSStoreOption func(*FSStore)
+
+// WithSecondaryCapacity returns a FSStoreOption which bounds the store's
+// total size on disk, evicting the least-recently-used entries once it's
+// exceeded. size accepts a number followed by an optional B/KB/MB/GB
+// suffix (binary, i.e. 1KB == 1024B), e.g. "64MB".
+func WithSecondaryCapacity(size string) FSStoreOption {
+	return func(s *FSStore) {
+		if n, err := parseByteSize(size); err == nil {
+			s.capacity = n
+		}
+	}
+}
+
+// NewFSStore constructs a FSStore rooted at baseDir, creating it if
+// necessary, and scans any entries already on disk (from a previous
+// process) to seed its size accounting.
+func NewFSStore(baseDir string, opt ...FSStoreOption) (*FSStore, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &FSStore{baseDir: baseDir}
+	for _, o := range opt {
+		o(s)
+	}
+	if err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			s.totalSize += info.Size()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// pathFor returns the on-disk path for key.
+func (s *FSStore) pathFor(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	return filepath.Join(s.baseDir, fmt.Sprintf("%02x", byte(sum)), fmt.Sprintf("%016x", sum))
+}
+
+// Get implements Store.
+func (s *FSStore) Get(key string) ([]byte, time.Time, bool) {
+	path := s.pathFor(key)
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) < 8 {
+		return nil, time.Time{}, false
+	}
+	expireAt := time.Unix(0, int64(binary.BigEndian.Uint64(data[:8])))
+	// mark as recently used for the mtime-based LRU eviction below.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data[8:], expireAt, true
+}
+
+// Set implements Store.
+func (s *FSStore) Set(key string, data []byte, expireAt time.Time) {
+	path := s.pathFor(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	buf := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(buf[:8], uint64(expireAt.UnixNano()))
+	copy(buf[8:], data)
+
+	var oldSize int64
+	if info, err := os.Stat(path); err == nil {
+		oldSize = info.Size()
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return
+	}
+
+	s.mu.Lock()
+	s.totalSize += int64(len(buf)) - oldSize
+	over := s.capacity > 0 && s.totalSize > s.capacity
+	s.mu.Unlock()
+
+	if over {
+		s.evictLRU()
+	}
+}
+
+// Delete implements Store.
+func (s *FSStore) Delete(key string) {
+	path := s.pathFor(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if os.Remove(path) == nil {
+		s.mu.Lock()
+		s.totalSize -= info.Size()
+		s.mu.Unlock()
+	}
+}
+
+// Purge implements Store.
+func (s *FSStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	os.RemoveAll(s.baseDir)
+	os.MkdirAll(s.baseDir, 0o755)
+	s.totalSize = 0
+}
+
+// evictLRU removes the oldest (by mtime) entries until the store is back
+// under capacity.
+func (s *FSStore) evictLRU() {
+	type fileInfo struct {
+		path  string
+		size  int64
+		mtime time.Time
+	}
+	var files []fileInfo
+	filepath.Walk(s.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		files = append(files, fileInfo{path: path, size: info.Size(), mtime: info.ModTime()})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range files {
+		if s.totalSize <= s.capacity {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			s.totalSize -= f.size
+		}
+	}
+}
+
+// parseByteSize parses sizes like "64MB", "512KB", or "128" (bytes) using
+// binary (1024-based) units.
+func parseByteSize(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(strings.ToUpper(size), u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(size[:len(size)-len(u.suffix)]), 64)
+			if err != nil {
+				return 0, err
+			}
+			return int64(n * float64(u.mult)), nil
+		}
+	}
+	return strconv.ParseInt(size, 10, 64)
+}
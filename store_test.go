@@ -0,0 +1,136 @@
+package lcache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFSStoreGetSetDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lcache")
+	s, err := NewFSStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSStore: %v", err)
+	}
+
+	expireAt := time.Now().Add(time.Hour)
+	s.Set("k1", []byte("hello"), expireAt)
+
+	data, got, ok := s.Get("k1")
+	if !ok {
+		t.Fatal("expected k1 to be present")
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected %q, got %q", "hello", data)
+	}
+	if !got.Equal(expireAt) {
+		t.Errorf("expected expireAt %v, got %v", expireAt, got)
+	}
+
+	s.Delete("k1")
+	if _, _, ok := s.Get("k1"); ok {
+		t.Error("expected k1 to be gone after Delete")
+	}
+}
+
+func TestFSStoreEvictsOverCapacity(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lcache")
+	s, err := NewFSStore(dir, WithSecondaryCapacity("1KB"))
+	if err != nil {
+		t.Fatalf("NewFSStore: %v", err)
+	}
+
+	payload := make([]byte, 512)
+	for i := 0; i < 10; i++ {
+		key := string(rune('a' + i))
+		s.Set(key, payload, time.Now().Add(time.Hour))
+		time.Sleep(time.Millisecond) // ensure distinct mtimes
+	}
+
+	if s.totalSize > s.capacity {
+		t.Errorf("expected store size to stay under capacity, got %d > %d", s.totalSize, s.capacity)
+	}
+	// the earliest keys should have been evicted, the most recent should remain.
+	if _, _, ok := s.Get("j"); !ok {
+		t.Error("expected most recently written key to survive eviction")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"128":   128,
+		"1KB":   1024,
+		"2MB":   2 * 1024 * 1024,
+		"1GB":   1024 * 1024 * 1024,
+		"0.5MB": 512 * 1024,
+	}
+	for in, want := range cases {
+		got, err := parseByteSize(in)
+		if err != nil {
+			t.Errorf("parseByteSize(%q) error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+type jsonPair struct {
+	A int
+	B string
+}
+
+func TestSecondaryStoreServesAfterRestart(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lcache")
+	store, err := NewFSStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSStore: %v", err)
+	}
+
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		return jsonPair{A: 1, B: "x"}, nil
+	}
+	c, _ := New(fn, time.Hour, WithSecondaryStore(store, JSONCodec{}, reflect.TypeOf(jsonPair{})))
+	val, err := c.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val.(jsonPair) != (jsonPair{A: 1, B: "x"}) {
+		t.Fatalf("unexpected value: %v", val)
+	}
+
+	// simulate a process restart: new container, new fn instance, but same store.
+	fn2 := func() (interface{}, error) {
+		calls++
+		return jsonPair{}, nil
+	}
+	c2, _ := New(fn2, time.Hour, WithSecondaryStore(store, JSONCodec{}, reflect.TypeOf(jsonPair{})))
+	val2, err := c2.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val2.(jsonPair) != (jsonPair{A: 1, B: "x"}) {
+		t.Fatalf("expected value served from secondary store, got %v", val2)
+	}
+	if calls != 1 {
+		t.Errorf("expected fn to be called exactly once (second read served from store), got %d calls", calls)
+	}
+}
+
+func TestWithSecondaryStoreRequiresOutType(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lcache")
+	store, err := NewFSStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSStore: %v", err)
+	}
+
+	fn := func() (interface{}, error) { return jsonPair{}, nil }
+	_, err = New(fn, time.Hour, WithSecondaryStore(store, JSONCodec{}, nil))
+	if err != ErrMissingStoreOutType {
+		t.Fatalf("expected ErrMissingStoreOutType, got %v", err)
+	}
+}
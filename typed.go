@@ -0,0 +1,193 @@
+package lcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// TypedLoader loads the value for a key. Used by TypedContainer instead of
+// the reflection-based fn accepted by Container.
+type TypedLoader[K comparable, V any] func(K) (V, error)
+
+// TypedContainer is a generic, reflection-free counterpart to Container.
+// Get pays no reflect.Call or fmt.Sprintf overhead: keys are used directly
+// instead of being formatted into a string, and loader is invoked through a
+// plain, statically-typed function value. For loaders that logically take
+// several parameters, make K a struct (or tuple-like array) of them.
+//
+// TypedContainer only implements the classic single-map LRU layout; use
+// Container for sharding, pluggable eviction policies, or the callback and
+// circuit-breaker integrations.
+type TypedContainer[K comparable, V any] struct {
+	sync.RWMutex
+	capacity int
+	loader   TypedLoader[K, V]
+	ttl      time.Duration
+
+	elements  map[K]*list.Element
+	evictList *list.List
+}
+
+// NewTypedContainer constructs a TypedContainer with the given capacity,
+// loader and ttl. A non-positive capacity uses DefaultCapacity.
+func NewTypedContainer[K comparable, V any](capacity int, loader TypedLoader[K, V], ttl time.Duration) *TypedContainer[K, V] {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &TypedContainer[K, V]{
+		capacity:  capacity,
+		loader:    loader,
+		ttl:       ttl,
+		elements:  make(map[K]*list.Element),
+		evictList: list.New(),
+	}
+}
+
+// typedItem is the generic counterpart to item.
+type typedItem[K comparable, V any] struct {
+	sync.Mutex
+	c   *TypedContainer[K, V]
+	key K
+
+	value    V
+	err      error
+	expireAt time.Time
+
+	initialed  bool
+	initialCh  chan struct{}
+	refreshing bool
+}
+
+func newTypedItem[K comparable, V any](c *TypedContainer[K, V], key K) *typedItem[K, V] {
+	return &typedItem[K, V]{c: c, key: key, initialCh: make(chan struct{})}
+}
+
+// Value returns the real value in the item, loading it first if needed.
+func (i *typedItem[K, V]) Value() (V, error) {
+	i.Lock()
+	fresh := time.Now().Before(i.expireAt)
+	val, err := i.value, i.err
+	i.Unlock()
+	if fresh {
+		return val, err
+	}
+	i.Refresh()
+	<-i.initialCh
+	i.Lock()
+	defer i.Unlock()
+	return i.value, i.err
+}
+
+// Refresh is used to refresh the real value with loader.
+func (i *typedItem[K, V]) Refresh() {
+	i.Lock()
+	if i.refreshing {
+		i.Unlock()
+		return
+	}
+	i.refreshing = true
+	go i.refresh()
+	i.Unlock()
+}
+
+func (i *typedItem[K, V]) refresh() {
+	val, err := i.c.loader(i.key)
+	i.Lock()
+	defer i.Unlock()
+	// don't cache response when underlying resouce exhausted
+	if err != ErrResourceExhausted {
+		i.value = val
+		i.err = err
+	}
+
+	i.expireAt = time.Now().Add(i.c.ttl)
+	i.refreshing = false
+	if !i.initialed {
+		i.initialed = true
+		close(i.initialCh)
+	}
+}
+
+// Get returns the value for key, loading it via loader on a miss.
+func (c *TypedContainer[K, V]) Get(key K) (V, error) {
+	c.RLock()
+	ent, ok := c.elements[key]
+	c.RUnlock()
+	if ok {
+		c.Lock()
+		c.evictList.MoveToFront(ent)
+		c.Unlock()
+		return ent.Value.(*typedItem[K, V]).Value()
+	}
+
+	c.Lock()
+	ent = c.getLocked(key)
+	c.Unlock()
+	return ent.Value.(*typedItem[K, V]).Value()
+}
+
+func (c *TypedContainer[K, V]) getLocked(key K) *list.Element {
+	if ent, ok := c.elements[key]; ok {
+		c.evictList.MoveToFront(ent)
+		return ent
+	}
+
+	itm := newTypedItem(c, key)
+	ent := c.evictList.PushFront(itm)
+
+	// copy on write
+	elements := make(map[K]*list.Element, len(c.elements)+1)
+	for k, v := range c.elements {
+		elements[k] = v
+	}
+	elements[key] = ent
+	c.elements = elements
+	if c.evictList.Len() > c.capacity {
+		c.removeOldestElement()
+	}
+	return ent
+}
+
+// removeOldestElement removes the oldest item from the container.
+func (c *TypedContainer[K, V]) removeOldestElement() {
+	ent := c.evictList.Back()
+	if ent != nil {
+		c.removeElement(ent)
+	}
+}
+
+// removeElement is used to remove a given list element from the container.
+func (c *TypedContainer[K, V]) removeElement(e *list.Element) {
+	c.evictList.Remove(e)
+	itm := e.Value.(*typedItem[K, V])
+	delete(c.elements, itm.key)
+}
+
+// Purge is used to completely clear the container.
+func (c *TypedContainer[K, V]) Purge() {
+	c.Lock()
+	defer c.Unlock()
+	for key := range c.elements {
+		delete(c.elements, key)
+	}
+	c.evictList.Init()
+}
+
+// Remove removes key from the container, returning if it was present.
+func (c *TypedContainer[K, V]) Remove(key K) bool {
+	c.Lock()
+	defer c.Unlock()
+	if ent, ok := c.elements[key]; ok {
+		c.removeElement(ent)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of items in the container.
+func (c *TypedContainer[K, V]) Len() int {
+	c.RLock()
+	defer c.RUnlock()
+	return len(c.elements)
+}
@@ -0,0 +1,78 @@
+package lcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTypedContainer(t *testing.T) {
+	loader := func(x int) (string, error) {
+		return "hello", nil
+	}
+	c := NewTypedContainer(2, loader, 300*time.Millisecond)
+
+	val, err := c.Get(1)
+	if err != nil || val != "hello" {
+		t.Fatalf("unexpected result: %v, %v", val, err)
+	}
+
+	if c.Len() != 1 {
+		t.Errorf("expected length 1, got %d", c.Len())
+	}
+}
+
+func TestTypedContainerEviction(t *testing.T) {
+	loader := func(x int) (int, error) {
+		return x * x, nil
+	}
+	c := NewTypedContainer(2, loader, time.Minute)
+
+	c.Get(1)
+	c.Get(2)
+	c.Get(3)
+
+	if c.Len() != 2 {
+		t.Errorf("expected length 2, got %d", c.Len())
+	}
+	if c.Remove(1) {
+		t.Errorf("expected key 1 to have been evicted")
+	}
+}
+
+func TestTypedContainerStructKey(t *testing.T) {
+	type key struct {
+		X, Y int
+	}
+	loader := func(k key) (int, error) {
+		return k.X + k.Y, nil
+	}
+	c := NewTypedContainer(10, loader, time.Minute)
+
+	sum, err := c.Get(key{X: 1, Y: 2})
+	if err != nil || sum != 3 {
+		t.Fatalf("unexpected result: %v, %v", sum, err)
+	}
+}
+
+// TestTypedContainerConcurrentGet drives many goroutines through Get on a
+// small, over-capacity keyspace so evictions race with the lock-free lookup
+// in Get. Run with -race to catch a torn read of c.elements.
+func TestTypedContainerConcurrentGet(t *testing.T) {
+	loader := func(x int) (int, error) {
+		return x * x, nil
+	}
+	c := NewTypedContainer(2, loader, time.Minute)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c.Get(i % 5)
+			}
+		}()
+	}
+	wg.Wait()
+}